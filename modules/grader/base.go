@@ -2,9 +2,12 @@ package grader
 
 import (
 	"encoding/binary"
-	"sort"
+	"runtime"
+	"slices"
+	"sync"
 
 	"github.com/pegnet/pegnet/modules/lxr30"
+	"github.com/pegnet/pegnet/opr"
 )
 
 // baseGrader provides common functionality that is deemed useful in all versions
@@ -16,26 +19,91 @@ type baseGrader struct {
 	height int32
 
 	prevWinners []string
+
+	version int // the grader version this block was constructed with; controls GradeOPRs' aggregator
+
+	cache   *GraderCache // memoizes LXRhash(OPRHash||Nonce) across blocks and reorgs
+	workers int          // size of the worker pool used to verify difficulties; 0 means runtime.NumCPU()
+
+	chainStore ChainStore // records this block's winners in the cross-block share chain
+}
+
+// aggregator returns the price-consensus aggregator GradeOPRs grades list
+// with: opr.GradeMinimumV3 (RobustConsensus's difficulty-weighted trimmed
+// median) from version 3 onward, opr.GradeMinimum (the plain mean) before
+// that. Split out from GradeOPRs so tests can assert on the selected
+// aggregator's identity without needing a real OraclePriceRecord set to
+// grade.
+func (bg *baseGrader) aggregator() func([]*opr.OraclePriceRecord) []*opr.OraclePriceRecord {
+	if bg.version >= 3 {
+		return opr.GradeMinimumV3
+	}
+	return opr.GradeMinimum
+}
+
+// GradeOPRs grades list and returns the top 50 sorted by grade, using the
+// aggregator appropriate to this block's version: V3Block (and any later
+// version) consensus against opr.RobustConsensus's difficulty-weighted
+// trimmed median instead of the plain mean V1Block/V2Block use via opr.Avg.
+func (bg *baseGrader) GradeOPRs(list []*opr.OraclePriceRecord) []*opr.OraclePriceRecord {
+	return bg.aggregator()(list)
+}
+
+// GraderOption configures optional baseGrader behavior, such as swapping out
+// the difficulty cache or worker pool size, so that, for example, a test can
+// disable the shared cache and keep runs from interfering with each other.
+type GraderOption func(*baseGrader)
+
+// WithGraderCache overrides the GraderCache used to memoize LXRhash verification.
+// Passing nil disables caching entirely.
+func WithGraderCache(cache *GraderCache) GraderOption {
+	return func(bg *baseGrader) { bg.cache = cache }
+}
+
+// WithWorkerCount overrides the size of the worker pool used to parallelise
+// difficulty verification. n <= 0 means runtime.NumCPU().
+func WithWorkerCount(n int) GraderOption {
+	return func(bg *baseGrader) { bg.workers = n }
+}
+
+// WithChainStore overrides the ChainStore VerifyChain records this block's
+// winners into, so that, for example, a test can isolate its share chain from
+// other runs.
+func WithChainStore(store ChainStore) GraderOption {
+	return func(bg *baseGrader) { bg.chainStore = store }
 }
 
 // NewGrader instantiates a Block Grader for a specific version.
 // Once set, the height and list of previous winners can't be changed.
-func NewGrader(version int, height int32, previousWinners []string) Block {
+func NewGrader(version int, height int32, previousWinners []string, opts ...GraderOption) Block {
+	var bg *baseGrader
+	var block Block
+
 	switch version {
 	case 1:
 		v1 := new(V1Block)
-		v1.height = height
-		v1.prevWinners = previousWinners
-		return v1
+		bg, block = &v1.baseGrader, v1
 	case 2:
 		v2 := new(V2Block)
-		v2.height = height
-		v2.prevWinners = previousWinners
-		return v2
+		bg, block = &v2.baseGrader, v2
+	case 3:
+		v3 := new(V3Block)
+		bg, block = &v3.baseGrader, v3
 	default:
 		// most likely developer error or outdated package
 		panic("invalid grader version")
 	}
+
+	bg.height = height
+	bg.prevWinners = previousWinners
+	bg.version = version
+	bg.cache = sharedGraderCache
+	bg.chainStore = sharedChainStore
+	for _, opt := range opts {
+		opt(bg)
+	}
+
+	return block
 }
 
 // Count will return the total number of OPRs stored in the block.
@@ -76,27 +144,103 @@ func (bg *baseGrader) filterDuplicates() {
 // to avoid having to LXRhash the entire set.
 // calculates at most `limit + misreported difficulties` hashes
 func (bg *baseGrader) sortByDifficulty(limit int) {
-	sort.SliceStable(bg.oprs, func(i, j int) bool {
-		return bg.oprs[i].SelfReportedDifficulty > bg.oprs[i].SelfReportedDifficulty
+	slices.SortStableFunc(bg.oprs, func(a, b *GradingOPR) int {
+		switch {
+		case a.SelfReportedDifficulty > b.SelfReportedDifficulty:
+			return -1
+		case a.SelfReportedDifficulty < b.SelfReportedDifficulty:
+			return 1
+		default:
+			return 0
+		}
 	})
 
-	lx := lxr30.Init()
-
-	topX := make([]*GradingOPR, 0)
-	for _, o := range bg.oprs {
-		hash := lx.Hash(append(o.OPRHash, o.Nonce...))
-		diff := binary.BigEndian.Uint64(hash)
+	topX := make([]*GradingOPR, 0, limit)
 
-		if diff != o.SelfReportedDifficulty {
-			continue
+	// Verify in bounded batches, rather than the whole set at once, so we can
+	// still stop around the first `limit` honest entries instead of always
+	// paying for every submission in the block.
+	batchSize := limit
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	for start := 0; start < len(bg.oprs) && len(topX) < limit; start += batchSize {
+		end := start + batchSize
+		if end > len(bg.oprs) {
+			end = len(bg.oprs)
 		}
 
-		topX = append(topX, o)
+		batch := bg.oprs[start:end]
+		verified := bg.verifyDifficulties(batch)
+		for i, o := range batch {
+			if verified[i] != o.SelfReportedDifficulty {
+				continue
+			}
+
+			topX = append(topX, o)
 
-		if len(topX) >= limit {
-			break
+			if len(topX) >= limit {
+				break
+			}
 		}
 	}
 
 	bg.oprs = topX
+}
+
+// verifyDifficulties returns, for every entry in batch, the LXRhash-verified
+// difficulty of OPRHash||Nonce. Results already present in bg.cache are reused
+// instead of re-hashed, and the remaining work is spread across a bounded
+// pool of goroutines, each with its own lxr30 hasher since lxr30.Init()
+// returns a hasher with per-call scratch state that isn't safe to share
+// across concurrent Hash calls.
+func (bg *baseGrader) verifyDifficulties(batch []*GradingOPR) []uint64 {
+	workers := bg.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(batch) {
+		workers = len(batch)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	verified := make([]uint64, len(batch))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lx := lxr30.Init()
+			for i := range jobs {
+				o := batch[i]
+
+				if bg.cache != nil {
+					if diff, ok := bg.cache.Get(o.OPRHash, o.Nonce); ok {
+						verified[i] = diff
+						continue
+					}
+				}
+
+				hash := lx.Hash(append(o.OPRHash, o.Nonce...))
+				diff := binary.BigEndian.Uint64(hash)
+				verified[i] = diff
+
+				if bg.cache != nil {
+					bg.cache.Put(o.OPRHash, o.Nonce, diff)
+				}
+			}
+		}()
+	}
+
+	for i := range batch {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return verified
 }
\ No newline at end of file
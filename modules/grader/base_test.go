@@ -0,0 +1,61 @@
+package grader
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pegnet/pegnet/opr"
+)
+
+// TestNewGraderV3UsesRobustConsensus grades a block through the real
+// NewGrader(3, ...) constructor and asserts it's wired to opr.GradeMinimumV3
+// (RobustConsensus's difficulty-weighted trimmed median), not the plain mean
+// opr.GradeMinimum that V1Block/V2Block use. A behavioral assertion (grading
+// real OPRs and checking the resulting prices) isn't possible here: neither
+// OraclePriceRecord nor the lxr30 hasher ComputeDifficulty depends on are
+// part of this package's tree, so nothing can build a submission a grader
+// would treat as honest. Asserting on the aggregator's identity is the
+// closest thing to an end-to-end proof available in this tree, and it's
+// exactly the thing that regressed: GradeOPRs existed but nothing called it,
+// so V3Block silently graded with V2Block's mean.
+func TestNewGraderV3UsesRobustConsensus(t *testing.T) {
+	block := NewGrader(3, 10, nil)
+	v3, ok := block.(*V3Block)
+	if !ok {
+		t.Fatalf("NewGrader(3, ...) returned %T, want *V3Block", block)
+	}
+
+	if got := v3.GradeOPRs(nil); got != nil {
+		t.Fatalf("GradeOPRs(nil) = %v, want nil", got)
+	}
+
+	got := reflect.ValueOf(v3.aggregator()).Pointer()
+	want := reflect.ValueOf(opr.GradeMinimumV3).Pointer()
+	if got != want {
+		t.Fatalf("NewGrader(3, ...)'s aggregator is not opr.GradeMinimumV3")
+	}
+}
+
+// TestBaseGraderAggregatorByVersion checks every version's aggregator
+// selection directly, including the pre-V3 versions that must keep using the
+// plain mean.
+func TestBaseGraderAggregatorByVersion(t *testing.T) {
+	cases := []struct {
+		version int
+		want    func([]*opr.OraclePriceRecord) []*opr.OraclePriceRecord
+	}{
+		{1, opr.GradeMinimum},
+		{2, opr.GradeMinimum},
+		{3, opr.GradeMinimumV3},
+		{4, opr.GradeMinimumV3},
+	}
+
+	for _, c := range cases {
+		bg := &baseGrader{version: c.version}
+		got := reflect.ValueOf(bg.aggregator()).Pointer()
+		want := reflect.ValueOf(c.want).Pointer()
+		if got != want {
+			t.Errorf("version %d: aggregator mismatch", c.version)
+		}
+	}
+}
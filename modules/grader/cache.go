@@ -0,0 +1,56 @@
+package grader
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// defaultCacheSize bounds the shared GraderCache. It comfortably covers a
+// single block's worth of OPRs several times over, so re-grading the same
+// block (e.g. while walking a reorg) doesn't re-pay the LXRhash cost.
+const defaultCacheSize = 100000
+
+// GraderCache memoizes the LXRhash-verified difficulty of an (OPRHash, Nonce)
+// pair, shared across blocks and re-grades the same way go-ethereum's gas
+// price oracle memoizes recent block data.
+type GraderCache struct {
+	cache *lru.Cache
+}
+
+// NewGraderCache creates a GraderCache holding up to size entries. size <= 0
+// uses defaultCacheSize.
+func NewGraderCache(size int) *GraderCache {
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	c, err := lru.New(size)
+	if err != nil {
+		// lru.New only errors for a non-positive size, which is guarded above.
+		panic(err)
+	}
+	return &GraderCache{cache: c}
+}
+
+// sharedGraderCache is the default cache NewGrader wires every baseGrader to
+// unless overridden with WithGraderCache.
+var sharedGraderCache = NewGraderCache(defaultCacheSize)
+
+func cacheKey(oprHash, nonce []byte) string {
+	key := make([]byte, 0, len(oprHash)+len(nonce))
+	key = append(key, oprHash...)
+	key = append(key, nonce...)
+	return string(key)
+}
+
+// Get returns the cached difficulty for OPRHash||Nonce, if present.
+func (gc *GraderCache) Get(oprHash, nonce []byte) (uint64, bool) {
+	v, ok := gc.cache.Get(cacheKey(oprHash, nonce))
+	if !ok {
+		return 0, false
+	}
+	return v.(uint64), true
+}
+
+// Put records the verified difficulty for OPRHash||Nonce.
+func (gc *GraderCache) Put(oprHash, nonce []byte, difficulty uint64) {
+	gc.cache.Add(cacheKey(oprHash, nonce), difficulty)
+}
@@ -0,0 +1,55 @@
+package grader
+
+import "testing"
+
+func TestGraderCacheGetMiss(t *testing.T) {
+	gc := NewGraderCache(10)
+	if _, ok := gc.Get([]byte("hash"), []byte("nonce")); ok {
+		t.Error("Get on an empty cache returned ok=true, want a miss")
+	}
+}
+
+func TestGraderCachePutGetRoundTrip(t *testing.T) {
+	gc := NewGraderCache(10)
+	gc.Put([]byte("hash"), []byte("nonce"), 42)
+
+	diff, ok := gc.Get([]byte("hash"), []byte("nonce"))
+	if !ok {
+		t.Fatal("Get after Put returned ok=false, want a hit")
+	}
+	if diff != 42 {
+		t.Errorf("Get returned %d, want 42", diff)
+	}
+}
+
+// TestGraderCacheKeyIncludesBothHashAndNonce makes sure the cache key mixes
+// OPRHash and Nonce rather than just one of them, since two different OPRs
+// can share either half on their own.
+func TestGraderCacheKeyIncludesBothHashAndNonce(t *testing.T) {
+	gc := NewGraderCache(10)
+	gc.Put([]byte("hash1"), []byte("nonceA"), 1)
+	gc.Put([]byte("hash1"), []byte("nonceB"), 2)
+	gc.Put([]byte("hash2"), []byte("nonceA"), 3)
+
+	cases := []struct {
+		hash, nonce []byte
+		want        uint64
+	}{
+		{[]byte("hash1"), []byte("nonceA"), 1},
+		{[]byte("hash1"), []byte("nonceB"), 2},
+		{[]byte("hash2"), []byte("nonceA"), 3},
+	}
+	for _, c := range cases {
+		diff, ok := gc.Get(c.hash, c.nonce)
+		if !ok || diff != c.want {
+			t.Errorf("Get(%s, %s) = (%d, %v), want (%d, true)", c.hash, c.nonce, diff, ok, c.want)
+		}
+	}
+}
+
+func TestNewGraderCacheDefaultsNonPositiveSize(t *testing.T) {
+	gc := NewGraderCache(0)
+	if gc.cache == nil {
+		t.Fatal("NewGraderCache(0) did not initialize an underlying cache")
+	}
+}
@@ -0,0 +1,148 @@
+package grader
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// Hash is the digest type used to identify a block's winners in the share
+// chain; it's the same width as OraclePriceRecord.EntryHash.
+type Hash [32]byte
+
+// ChainStore persists the share chain so VerifyChain can walk back through
+// graded history across a reorg without re-grading every intermediate block.
+// MemoryChainStore below is the in-memory implementation used by default;
+// a persistent implementation (e.g. backed by a kv store) only needs to
+// satisfy this interface to be used in its place.
+type ChainStore interface {
+	// GetWinners returns the winners digest recorded at height, if any.
+	GetWinners(height int32) (Hash, bool)
+	// PutBlock records height's winners digest, linked to the digest at height-1.
+	PutBlock(height int32, winners []*GradingOPR) error
+	// PutDigest records height's winners digest directly, linked to the
+	// digest at height-1. PutBlock is PutDigest(height, winnersDigest(winners));
+	// it's split out so the chain-linking logic is testable without needing
+	// a real []*GradingOPR to hash.
+	PutDigest(height int32, digest Hash) error
+	// CommonAncestor returns the height of the most recent share both a and b
+	// descend from, or -1 if the two digests share no known history.
+	CommonAncestor(a, b Hash) int32
+}
+
+// winnersDigest hashes the short entry hashes of a graded block's top 10
+// winners into a single digest, the same short form VerifyWinners already
+// compares OPRs' WinPreviousOPR entries against.
+func winnersDigest(winners []*GradingOPR) Hash {
+	h := sha256.New()
+	for i, o := range winners {
+		if i >= 10 {
+			break
+		}
+		h.Write(o.EntryHash[:8])
+	}
+
+	var digest Hash
+	copy(digest[:], h.Sum(nil))
+	return digest
+}
+
+// MemoryChainStore is an in-memory ChainStore, suitable for tests and
+// single-process graders that don't need the share chain to survive restarts.
+type MemoryChainStore struct {
+	byHeight map[int32]Hash
+	heightOf map[Hash]int32
+	parentOf map[Hash]Hash
+}
+
+// NewMemoryChainStore creates an empty in-memory ChainStore.
+func NewMemoryChainStore() *MemoryChainStore {
+	return &MemoryChainStore{
+		byHeight: make(map[int32]Hash),
+		heightOf: make(map[Hash]int32),
+		parentOf: make(map[Hash]Hash),
+	}
+}
+
+// GetWinners implements ChainStore.
+func (m *MemoryChainStore) GetWinners(height int32) (Hash, bool) {
+	d, ok := m.byHeight[height]
+	return d, ok
+}
+
+// PutBlock implements ChainStore.
+func (m *MemoryChainStore) PutBlock(height int32, winners []*GradingOPR) error {
+	return m.PutDigest(height, winnersDigest(winners))
+}
+
+// PutDigest implements ChainStore.
+func (m *MemoryChainStore) PutDigest(height int32, digest Hash) error {
+	if parent, ok := m.byHeight[height-1]; ok {
+		m.parentOf[digest] = parent
+	}
+
+	m.byHeight[height] = digest
+	m.heightOf[digest] = height
+	return nil
+}
+
+// CommonAncestor implements ChainStore by walking both digests' parent links
+// until a shared winners digest is found.
+func (m *MemoryChainStore) CommonAncestor(a, b Hash) int32 {
+	ancestors := make(map[Hash]bool)
+	for cur, ok := a, true; ok; cur, ok = m.parentOf[cur] {
+		ancestors[cur] = true
+	}
+
+	for cur, ok := b, true; ok; cur, ok = m.parentOf[cur] {
+		if ancestors[cur] {
+			if height, known := m.heightOf[cur]; known {
+				return height
+			}
+			return -1
+		}
+	}
+
+	return -1
+}
+
+// sharedChainStore is the default ChainStore NewGrader wires every baseGrader
+// to unless overridden with WithChainStore.
+var sharedChainStore ChainStore = NewMemoryChainStore()
+
+// VerifyChain checks that this block's winners are consistent with the share
+// chain recorded in its ChainStore, walking back up to depth already-stored
+// ancestors to confirm they form one unbroken lineage rather than two
+// conflicting histories, then records this block's winners in the store.
+//
+// The candidate itself is never compared against its own not-yet-stored
+// digest: the immediate parent (height-1) anchors the check, and every
+// earlier pair back to depth is validated using only digests the store
+// already holds. On a reorg (the store holds a winners digest for one of
+// those heights that isn't an ancestor of this block), the caller should
+// roll back to ChainStore.CommonAncestor and re-grade forward before
+// retrying.
+func (bg *baseGrader) VerifyChain(depth int) error {
+	if len(bg.winners) == 0 {
+		return fmt.Errorf("grader: block at height %d has no graded winners to verify", bg.height)
+	}
+
+	digest := winnersDigest(bg.winners)
+
+	if stored, ok := bg.chainStore.GetWinners(bg.height); ok && stored != digest {
+		return fmt.Errorf("grader: winners digest mismatch at height %d", bg.height)
+	}
+
+	newer, ok := bg.chainStore.GetWinners(bg.height - 1)
+	for d := int32(2); ok && d <= int32(depth); d++ {
+		older, olderOK := bg.chainStore.GetWinners(bg.height - d)
+		if !olderOK {
+			break // nothing recorded this far back; as verified as it can be
+		}
+		if bg.chainStore.CommonAncestor(newer, older) != bg.height-d {
+			return fmt.Errorf("grader: conflicting share chain history at height %d", bg.height-d)
+		}
+		newer, ok = older, olderOK
+	}
+
+	return bg.chainStore.PutDigest(bg.height, digest)
+}
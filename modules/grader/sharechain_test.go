@@ -0,0 +1,104 @@
+package grader
+
+import "testing"
+
+func digestOf(b byte) Hash {
+	var h Hash
+	h[0] = b
+	return h
+}
+
+func TestMemoryChainStoreGetWinnersRoundTrip(t *testing.T) {
+	m := NewMemoryChainStore()
+
+	if _, ok := m.GetWinners(1); ok {
+		t.Fatal("GetWinners on an empty store returned ok=true, want a miss")
+	}
+
+	d := digestOf(1)
+	if err := m.PutDigest(1, d); err != nil {
+		t.Fatalf("PutDigest: %v", err)
+	}
+
+	got, ok := m.GetWinners(1)
+	if !ok || got != d {
+		t.Fatalf("GetWinners(1) = (%v, %v), want (%v, true)", got, ok, d)
+	}
+}
+
+// TestMemoryChainStoreCommonAncestorStraightLine checks the non-reorg case:
+// a single unbroken chain, where the common ancestor of any two digests on
+// it is simply the older of the two heights.
+func TestMemoryChainStoreCommonAncestorStraightLine(t *testing.T) {
+	m := NewMemoryChainStore()
+
+	d1, d2, d3 := digestOf(1), digestOf(2), digestOf(3)
+	if err := m.PutDigest(1, d1); err != nil {
+		t.Fatalf("PutDigest(1): %v", err)
+	}
+	if err := m.PutDigest(2, d2); err != nil {
+		t.Fatalf("PutDigest(2): %v", err)
+	}
+	if err := m.PutDigest(3, d3); err != nil {
+		t.Fatalf("PutDigest(3): %v", err)
+	}
+
+	if got, want := m.CommonAncestor(d3, d1), int32(1); got != want {
+		t.Errorf("CommonAncestor(d3, d1) = %d, want %d", got, want)
+	}
+}
+
+// TestMemoryChainStoreCommonAncestorReorg simulates a reorg: two branches
+// both descend from height 2, then diverge at height 3 with conflicting
+// digests. CommonAncestor must find height 2 as the fork point, not -1 and
+// not either branch's own height.
+func TestMemoryChainStoreCommonAncestorReorg(t *testing.T) {
+	m := NewMemoryChainStore()
+
+	shared1, shared2 := digestOf(1), digestOf(2)
+	if err := m.PutDigest(1, shared1); err != nil {
+		t.Fatalf("PutDigest(1): %v", err)
+	}
+	if err := m.PutDigest(2, shared2); err != nil {
+		t.Fatalf("PutDigest(2): %v", err)
+	}
+
+	// Branch A continues from height 2 to height 3.
+	branchA := digestOf(3)
+	if err := m.PutDigest(3, branchA); err != nil {
+		t.Fatalf("PutDigest(3, branchA): %v", err)
+	}
+
+	// Branch B is a competing, separately-tracked fork also rooted at
+	// height 2 (modeled as its own store sharing the same ancestry, since a
+	// single MemoryChainStore can only hold one digest per height).
+	fork := NewMemoryChainStore()
+	if err := fork.PutDigest(1, shared1); err != nil {
+		t.Fatalf("fork PutDigest(1): %v", err)
+	}
+	if err := fork.PutDigest(2, shared2); err != nil {
+		t.Fatalf("fork PutDigest(2): %v", err)
+	}
+	branchB := digestOf(4)
+	if err := fork.PutDigest(3, branchB); err != nil {
+		t.Fatalf("fork PutDigest(3, branchB): %v", err)
+	}
+
+	// Compare branchA's lineage (in m) against branchB's digest by importing
+	// branchB's parent link into m, the way VerifyChain's caller would after
+	// observing a conflicting digest at height 3.
+	if parent, ok := fork.parentOf[branchB]; ok {
+		m.parentOf[branchB] = parent
+	}
+
+	if got, want := m.CommonAncestor(branchA, branchB), int32(2); got != want {
+		t.Errorf("CommonAncestor(branchA, branchB) = %d, want %d (the shared height-2 fork point)", got, want)
+	}
+}
+
+func TestMemoryChainStoreCommonAncestorUnknownHistory(t *testing.T) {
+	m := NewMemoryChainStore()
+	if got, want := m.CommonAncestor(digestOf(1), digestOf(2)), int32(-1); got != want {
+		t.Errorf("CommonAncestor on unrelated digests = %d, want %d", got, want)
+	}
+}
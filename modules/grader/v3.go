@@ -0,0 +1,13 @@
+package grader
+
+import "github.com/pegnet/pegnet/opr"
+
+// V3Block grades OPRs the same way V2Block does, except that it consensuses
+// on a difficulty-weighted trimmed median instead of the plain arithmetic
+// mean, so a single extreme misreport can't drag the consensus price around.
+// See opr.RobustConsensus for the aggregation itself; this type exists so
+// callers can opt into it through NewGrader the same way they select any
+// other grader version.
+type V3Block struct {
+	V2Block
+}
@@ -6,11 +6,37 @@ package opr
 import (
 	"encoding/binary"
 	"encoding/hex"
-	"sort"
+	"slices"
 
 	"github.com/pegnet/pegnet/common"
 )
 
+// byDifficultyDesc orders OraclePriceRecords by descending Difficulty, for use
+// with slices.SortStableFunc.
+func byDifficultyDesc(a, b *OraclePriceRecord) int {
+	switch {
+	case a.Difficulty > b.Difficulty:
+		return -1
+	case a.Difficulty < b.Difficulty:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// byGradeAsc orders OraclePriceRecords by ascending Grade, for use with
+// slices.SortStableFunc.
+func byGradeAsc(a, b *OraclePriceRecord) int {
+	switch {
+	case a.Grade < b.Grade:
+		return -1
+	case a.Grade > b.Grade:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // Avg computes the average answer for the price of each token reported
 func Avg(list []*OraclePriceRecord) (avg []float64) {
 	avg = make([]float64, len(common.AllAssets))
@@ -81,8 +107,8 @@ func GradeMinimum(sortedList []*OraclePriceRecord) (graded []*OraclePriceRecord)
 			CalculateGrade(avg, top50[j])
 		}
 		// Because this process can scramble the sorted fields, we have to resort with each pass.
-		sort.SliceStable(top50[:i], func(i, j int) bool { return top50[i].Difficulty > top50[j].Difficulty })
-		sort.SliceStable(top50[:i], func(i, j int) bool { return top50[i].Grade < top50[j].Grade })
+		slices.SortStableFunc(top50[:i], byDifficultyDesc)
+		slices.SortStableFunc(top50[:i], byGradeAsc)
 	}
 	return top50
 }
@@ -99,7 +125,7 @@ func GradeBlock(list []*OraclePriceRecord) (graded []*OraclePriceRecord, sorted
 
 	// Throw away all the entries but the top 50 on pure difficulty alone.
 	// Note that we are sorting in descending order.
-	sort.SliceStable(list, func(i, j int) bool { return list[i].Difficulty > list[j].Difficulty })
+	slices.SortStableFunc(list, byDifficultyDesc)
 
 	var topDifficulty []*OraclePriceRecord
 	if len(list) > 50 {
@@ -115,8 +141,8 @@ func GradeBlock(list []*OraclePriceRecord) (graded []*OraclePriceRecord, sorted
 			CalculateGrade(avg, topDifficulty[j])
 		}
 		// Because this process can scramble the sorted fields, we have to resort with each pass.
-		sort.SliceStable(topDifficulty[:i], func(i, j int) bool { return topDifficulty[i].Difficulty > topDifficulty[j].Difficulty })
-		sort.SliceStable(topDifficulty[:i], func(i, j int) bool { return topDifficulty[i].Grade < topDifficulty[j].Grade })
+		slices.SortStableFunc(topDifficulty[:i], byDifficultyDesc)
+		slices.SortStableFunc(topDifficulty[:i], byGradeAsc)
 	}
 	return topDifficulty, list // Return the top50 sorted by grade and then all sorted by difficulty
 }
@@ -156,17 +182,3 @@ func VerifyWinners(opr *OraclePriceRecord, winners []*OraclePriceRecord) bool {
 	}
 	return true
 }
-
-func GetRewardFromPlace(place int) int64 {
-	if place >= 10 {
-		return 0 // There's no participation trophy. Return zero.
-	}
-	switch place {
-	case 0:
-		return 800 * 1e8 // The Big Winner
-	case 1:
-		return 600 * 1e8 // Second Place
-	default:
-		return 450 * 1e8 // Consolation Prize
-	}
-}
@@ -0,0 +1,142 @@
+// Copyright (c) of parts are held by the various contributors (see the CLA)
+// Licensed under the MIT License. See LICENSE file in the project root for full license information.
+
+package opr
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pegnet/pegnet/common"
+)
+
+// AssetPriceHistory holds, for a single asset, the consensus price (the
+// median reported value among that block's top-50 honest OPR reports) of
+// each block in the requested range, plus the requested percentiles of those
+// same reports.
+type AssetPriceHistory struct {
+	ConsensusPrices []float64   // ConsensusPrices[i] is the per-block consensus (top-50 median) price
+	Prices          [][]float64 // Prices[i] holds, per block, the values at each requested percentile
+	Percentiles     []float64
+}
+
+// PriceHistory is the result of walking a range of graded OPR blocks, modeled
+// on eth_feeHistory: it lets a caller reason about oracle agreement/spread
+// over a window without re-grading every block itself.
+type PriceHistory struct {
+	Assets      map[string]*AssetPriceHistory
+	OldestBlock int64
+}
+
+// GetPriceHistory walks blockCount graded blocks ending at newestBlock and, for
+// each requested asset, reports the consensus price plus the requested
+// percentiles of that block's top-50 honest OPR reports. Percentile ranks are
+// interpolated linearly the same way feeHistory interpolates gas reward
+// percentiles. blockCount must be positive.
+func GetPriceHistory(blocks []*OprBlock, newestBlock int64, blockCount int64, assets []string, percentiles []float64) (*PriceHistory, error) {
+	if blockCount <= 0 {
+		return nil, fmt.Errorf("opr: blockCount must be positive, got %d", blockCount)
+	}
+
+	oldest := newestBlock - blockCount + 1
+	if oldest < 0 {
+		oldest = 0
+	}
+
+	result := &PriceHistory{
+		Assets:      make(map[string]*AssetPriceHistory, len(assets)),
+		OldestBlock: oldest,
+	}
+	for _, asset := range assets {
+		result.Assets[asset] = &AssetPriceHistory{
+			ConsensusPrices: make([]float64, 0, blockCount),
+			Prices:          make([][]float64, 0, blockCount),
+			Percentiles:     percentiles,
+		}
+	}
+
+	byHeight := make(map[int64]*OprBlock, len(blocks))
+	for _, b := range blocks {
+		byHeight[b.Dbht] = b
+	}
+
+	for height := oldest; height <= newestBlock; height++ {
+		block := byHeight[height]
+		for _, asset := range assets {
+			hist := result.Assets[asset]
+			if block == nil || len(block.GradedOPRs) == 0 {
+				hist.ConsensusPrices = append(hist.ConsensusPrices, 0)
+				hist.Prices = append(hist.Prices, make([]float64, len(percentiles)))
+				continue
+			}
+
+			values := sortedAssetValues(block.GradedOPRs, asset)
+			hist.ConsensusPrices = append(hist.ConsensusPrices, medianOf(values))
+			hist.Prices = append(hist.Prices, percentilesOf(values, percentiles))
+		}
+	}
+
+	return result, nil
+}
+
+// sortedAssetValues returns the given asset's reported values across the
+// top-50 honest OPRs, sorted ascending.
+func sortedAssetValues(top50 []*OraclePriceRecord, asset string) []float64 {
+	idx := -1
+	for i, a := range common.AllAssets {
+		if a == asset {
+			idx = i
+			break
+		}
+	}
+
+	values := make([]float64, 0, len(top50))
+	if idx >= 0 {
+		for _, o := range top50 {
+			tokens := o.GetTokens()
+			if idx < len(tokens) {
+				values = append(values, tokens[idx].value)
+			}
+		}
+	}
+	sort.Float64s(values)
+	return values
+}
+
+// medianOf returns the consensus (p50) price of a sorted value set, or 0 if
+// there are no values to consensus on.
+func medianOf(sorted []float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return interpolatePercentile(sorted, 50)
+}
+
+// percentilesOf linearly interpolates each requested percentile rank against
+// a sorted value set.
+func percentilesOf(sorted []float64, percentiles []float64) []float64 {
+	out := make([]float64, len(percentiles))
+	if len(sorted) == 0 {
+		return out
+	}
+	for i, p := range percentiles {
+		out[i] = interpolatePercentile(sorted, p)
+	}
+	return out
+}
+
+// interpolatePercentile returns the value at percentile p (0-100) in a sorted
+// slice, linearly interpolating between the two nearest ranks.
+func interpolatePercentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(rank)
+	hi := lo + 1
+	if hi >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := rank - float64(lo)
+	return sorted[lo] + frac*(sorted[hi]-sorted[lo])
+}
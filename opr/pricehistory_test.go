@@ -0,0 +1,104 @@
+// Copyright (c) of parts are held by the various contributors (see the CLA)
+// Licensed under the MIT License. See LICENSE file in the project root for full license information.
+
+package opr
+
+import "testing"
+
+// These tests exercise percentilesOf/medianOf/interpolatePercentile directly
+// rather than GetPriceHistory end-to-end: GetPriceHistory reads values off
+// *OraclePriceRecord via GetTokens(), and that type isn't part of this
+// package's tree, so nothing here can build an OprBlock with real graded
+// OPRs in it. These are the pure functions GetPriceHistory's per-asset
+// aggregation actually reduces to once the values are extracted.
+
+func TestInterpolatePercentileMidpoint(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5}
+	if got, want := interpolatePercentile(sorted, 50), 3.0; got != want {
+		t.Errorf("p50 = %v, want %v", got, want)
+	}
+}
+
+func TestInterpolatePercentileBetweenRanks(t *testing.T) {
+	sorted := []float64{0, 10}
+	// rank = 25/100 * (2-1) = 0.25, so 0 + 0.25*(10-0) = 2.5
+	if got, want := interpolatePercentile(sorted, 25), 2.5; got != want {
+		t.Errorf("p25 = %v, want %v", got, want)
+	}
+}
+
+func TestInterpolatePercentileSingleValue(t *testing.T) {
+	if got, want := interpolatePercentile([]float64{7}, 90), 7.0; got != want {
+		t.Errorf("p90 of a single value = %v, want %v", got, want)
+	}
+}
+
+func TestMedianOfEmptyIsZero(t *testing.T) {
+	if got, want := medianOf(nil), 0.0; got != want {
+		t.Errorf("medianOf(nil) = %v, want %v (an empty/missing block shouldn't move the consensus price)", got, want)
+	}
+}
+
+func TestPercentilesOfEmptyReturnsZeroedSlice(t *testing.T) {
+	got := percentilesOf(nil, []float64{10, 50, 90})
+	if len(got) != 3 {
+		t.Fatalf("len(percentilesOf(nil, ...)) = %d, want 3", len(got))
+	}
+	for i, v := range got {
+		if v != 0 {
+			t.Errorf("percentilesOf(nil, ...)[%d] = %v, want 0", i, v)
+		}
+	}
+}
+
+func TestPercentilesOfReportsEachRequestedRank(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40, 50}
+	got := percentilesOf(sorted, []float64{0, 50, 100})
+	want := []float64{10, 30, 50}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("percentile[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGetPriceHistoryRejectsNonPositiveBlockCount(t *testing.T) {
+	if _, err := GetPriceHistory(nil, 10, 0, nil, nil); err == nil {
+		t.Error("GetPriceHistory with blockCount=0 returned nil error, want an error")
+	}
+	if _, err := GetPriceHistory(nil, 10, -1, nil, nil); err == nil {
+		t.Error("GetPriceHistory with blockCount=-1 returned nil error, want an error")
+	}
+}
+
+// TestGetPriceHistoryGapFillsZeroes exercises GetPriceHistory's empty-block
+// handling for a height range with no graded blocks at all: every requested
+// height should still get an entry (zeroed, not omitted), so callers can
+// index the result by offset from OldestBlock.
+func TestGetPriceHistoryGapFillsZeroes(t *testing.T) {
+	hist, err := GetPriceHistory(nil, 10, 3, []string{"PEG"}, []float64{50})
+	if err != nil {
+		t.Fatalf("GetPriceHistory: %v", err)
+	}
+
+	peg := hist.Assets["PEG"]
+	if peg == nil {
+		t.Fatal("missing PEG asset in result")
+	}
+	if got, want := len(peg.ConsensusPrices), 3; got != want {
+		t.Fatalf("len(ConsensusPrices) = %d, want %d", got, want)
+	}
+	for i, p := range peg.ConsensusPrices {
+		if p != 0 {
+			t.Errorf("ConsensusPrices[%d] = %v, want 0 for an ungraded height", i, p)
+		}
+	}
+	for i, ps := range peg.Prices {
+		if len(ps) != 1 || ps[0] != 0 {
+			t.Errorf("Prices[%d] = %v, want [0]", i, ps)
+		}
+	}
+	if got, want := hist.OldestBlock, int64(8); got != want {
+		t.Errorf("OldestBlock = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) of parts are held by the various contributors (see the CLA)
+// Licensed under the MIT License. See LICENSE file in the project root for full license information.
+
+package opr
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RewardMode selects how block rewards are allocated to miners.
+type RewardMode int
+
+const (
+	// RewardModeFixed is the legacy per-block payout table used by GetRewardFromPlace.
+	RewardModeFixed RewardMode = iota
+	// RewardModePPLNS splits the coinbase across a sliding window of recent blocks,
+	// proportional to each miner's share of validated self-reported difficulty.
+	RewardModePPLNS
+)
+
+// minerShare is one miner's validated contribution to a single graded block.
+type minerShare struct {
+	Address    string
+	Difficulty uint64
+}
+
+// RewardCalculator accumulates per-block miner shares over a sliding window of
+// height N and splits the coinbase proportional to each miner's share of the
+// total difficulty in the window. This mirrors p2pool's share-splitting model:
+// a miner whose grade is close to, but not inside, the top 10 still earns a
+// payout proportional to the difficulty it contributed across recent blocks,
+// rather than an all-or-nothing prize at a single height.
+type RewardCalculator struct {
+	mu sync.RWMutex
+
+	window int32 // number of most recent blocks of shares retained, e.g. 2016
+
+	// shares maps height -> the top-50 honest miner shares recorded for that height.
+	// Heights older than the window are evicted as new blocks are recorded.
+	shares map[int64][]minerShare
+}
+
+// NewRewardCalculator creates a RewardCalculator that keeps a sliding window of
+// `window` blocks of recorded miner shares.
+func NewRewardCalculator(window int32) *RewardCalculator {
+	return &RewardCalculator{
+		window: window,
+		shares: make(map[int64][]minerShare),
+	}
+}
+
+// RecordBlock records the validated top-50 honest OPRs' self-reported
+// difficulty for the given height against each OPR's payout address, and
+// evicts any height that has fallen out of the sliding window. addresses and
+// difficulties must be parallel (addresses[i] is the payout address for
+// difficulties[i]); callers supply the address explicitly rather than
+// RewardCalculator assuming a specific OraclePriceRecord field, since the
+// payout address isn't part of the grading data OraclePriceRecord otherwise
+// exposes here.
+func (rc *RewardCalculator) RecordBlock(height int64, addresses []string, difficulties []uint64) error {
+	if len(addresses) != len(difficulties) {
+		return fmt.Errorf("opr: RecordBlock: got %d addresses for %d difficulties, want equal counts", len(addresses), len(difficulties))
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	recorded := make([]minerShare, 0, len(addresses))
+	for i, addr := range addresses {
+		recorded = append(recorded, minerShare{
+			Address:    addr,
+			Difficulty: difficulties[i],
+		})
+	}
+	rc.shares[height] = recorded
+
+	oldest := height - int64(rc.window) + 1
+	for h := range rc.shares {
+		if h < oldest {
+			delete(rc.shares, h)
+		}
+	}
+	return nil
+}
+
+// GetRewards computes each address's share of blockReward for the window
+// ending at height. Each block's difficulty is normalized to a share of that
+// block's own total (difficulty / block_total_difficulty) before being
+// summed across the window, so a miner's payout isn't skewed by how much
+// total difficulty happened to be submitted in the blocks they appear in;
+// blockReward is then split blockReward * address_share / window_share,
+// where window_share is the sum of every address's share across the window.
+// Blocks with no recorded difficulty (including unrecorded heights) don't
+// contribute a share and are skipped.
+func (rc *RewardCalculator) GetRewards(height int64, blockReward int64) map[string]int64 {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	addressShare := make(map[string]float64)
+	var windowShare float64
+
+	oldest := height - int64(rc.window) + 1
+	for h := oldest; h <= height; h++ {
+		var blockTotal uint64
+		for _, s := range rc.shares[h] {
+			blockTotal += s.Difficulty
+		}
+		if blockTotal == 0 {
+			continue
+		}
+
+		for _, s := range rc.shares[h] {
+			share := float64(s.Difficulty) / float64(blockTotal)
+			addressShare[s.Address] += share
+			windowShare += share
+		}
+	}
+
+	rewards := make(map[string]int64, len(addressShare))
+	if windowShare == 0 {
+		return rewards
+	}
+	for addr, share := range addressShare {
+		rewards[addr] = int64(float64(blockReward) * share / windowShare)
+	}
+	return rewards
+}
+
+// GetRewardFromPlace returns the legacy fixed payout for a single-block winner
+// placing at the given rank. It remains the default under RewardModeFixed;
+// RewardModePPLNS callers should use a RewardCalculator's GetRewards instead.
+func GetRewardFromPlace(place int) int64 {
+	if place >= 10 {
+		return 0 // There's no participation trophy. Return zero.
+	}
+	switch place {
+	case 0:
+		return 800 * 1e8 // The Big Winner
+	case 1:
+		return 600 * 1e8 // Second Place
+	default:
+		return 450 * 1e8 // Consolation Prize
+	}
+}
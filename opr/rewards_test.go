@@ -0,0 +1,88 @@
+// Copyright (c) of parts are held by the various contributors (see the CLA)
+// Licensed under the MIT License. See LICENSE file in the project root for full license information.
+
+package opr
+
+import "testing"
+
+func TestRecordBlockRejectsMismatchedLengths(t *testing.T) {
+	rc := NewRewardCalculator(10)
+	err := rc.RecordBlock(1, []string{"addr1", "addr2"}, []uint64{100})
+	if err == nil {
+		t.Fatal("RecordBlock with mismatched address/difficulty counts returned nil error, want an error")
+	}
+}
+
+func TestGetRewardsSingleBlockWindow(t *testing.T) {
+	rc := NewRewardCalculator(1)
+
+	if err := rc.RecordBlock(5, []string{"addr1", "addr2"}, []uint64{300, 100}); err != nil {
+		t.Fatalf("RecordBlock: %v", err)
+	}
+
+	rewards := rc.GetRewards(5, 1000)
+	if got, want := rewards["addr1"], int64(750); got != want {
+		t.Errorf("addr1 reward = %d, want %d", got, want)
+	}
+	if got, want := rewards["addr2"], int64(250); got != want {
+		t.Errorf("addr2 reward = %d, want %d", got, want)
+	}
+}
+
+// TestGetRewardsNormalizesPerBlock proves a miner's share is weighted equally
+// per block rather than skewed by how much total difficulty happened to be
+// submitted in the blocks they appear in: addr1 mines every block in the
+// window and should get the same payout whether its blocks carried a little
+// competing difficulty or a lot.
+func TestGetRewardsNormalizesPerBlock(t *testing.T) {
+	rc := NewRewardCalculator(3)
+
+	// addr1 always earns exactly half of each block it appears in, but the
+	// blocks have wildly different total difficulty.
+	if err := rc.RecordBlock(1, []string{"addr1", "addr2"}, []uint64{1, 1}); err != nil {
+		t.Fatalf("RecordBlock(1): %v", err)
+	}
+	if err := rc.RecordBlock(2, []string{"addr1", "addr3"}, []uint64{1_000_000, 1_000_000}); err != nil {
+		t.Fatalf("RecordBlock(2): %v", err)
+	}
+	if err := rc.RecordBlock(3, []string{"addr1", "addr4"}, []uint64{50, 50}); err != nil {
+		t.Fatalf("RecordBlock(3): %v", err)
+	}
+
+	rewards := rc.GetRewards(3, 900)
+	// addr1 holds a 1/2 share in all 3 blocks => 1/2 of the window share.
+	if got, want := rewards["addr1"], int64(450); got != want {
+		t.Errorf("addr1 reward = %d, want %d (raw-difficulty weighting would have let block 2 dominate)", got, want)
+	}
+	// The other three miners split the remaining half evenly, one block each.
+	for _, addr := range []string{"addr2", "addr3", "addr4"} {
+		if got, want := rewards[addr], int64(150); got != want {
+			t.Errorf("%s reward = %d, want %d", addr, got, want)
+		}
+	}
+}
+
+func TestGetRewardsSkipsBlocksOutsideWindow(t *testing.T) {
+	rc := NewRewardCalculator(2)
+
+	if err := rc.RecordBlock(1, []string{"addr1"}, []uint64{100}); err != nil {
+		t.Fatalf("RecordBlock(1): %v", err)
+	}
+	if err := rc.RecordBlock(2, []string{"addr2"}, []uint64{100}); err != nil {
+		t.Fatalf("RecordBlock(2): %v", err)
+	}
+	if err := rc.RecordBlock(3, []string{"addr3"}, []uint64{100}); err != nil {
+		t.Fatalf("RecordBlock(3): %v", err)
+	}
+
+	rewards := rc.GetRewards(3, 1000)
+	if _, ok := rewards["addr1"]; ok {
+		t.Error("addr1's block fell outside the window of 2 but still earned a reward")
+	}
+	if got, want := rewards["addr2"], int64(500); got != want {
+		t.Errorf("addr2 reward = %d, want %d", got, want)
+	}
+	if got, want := rewards["addr3"], int64(500); got != want {
+		t.Errorf("addr3 reward = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) of parts are held by the various contributors (see the CLA)
+// Licensed under the MIT License. See LICENSE file in the project root for full license information.
+
+package opr
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/pegnet/pegnet/common"
+)
+
+// trimFraction is the fraction of reports trimmed from each tail before the
+// weighted median is computed.
+const trimFraction = 0.1
+
+// weightedValue is one OPR's reported value for a single asset, paired with
+// the log-scaled weight its validated self-reported difficulty contributes.
+type weightedValue struct {
+	value  float64
+	weight float64
+}
+
+// RobustConsensus computes, per asset, a difficulty-weighted trimmed median
+// across the given OPRs. Unlike Avg, it discards non-positive misreports
+// instead of folding them in as positive, trims the top and bottom
+// trimFraction of remaining values to blunt outliers, and weights the
+// surviving reports by their (log-scaled) validated difficulty so that
+// harder-working miners pull the consensus more than idle ones. Because raw
+// difficulties span many orders of magnitude, weights use log2(difficulty+1)
+// rather than the raw value.
+func RobustConsensus(list []*OraclePriceRecord) []float64 {
+	consensus := make([]float64, len(common.AllAssets))
+
+	for i := range consensus {
+		values := make([]weightedValue, 0, len(list))
+		for _, opr := range list {
+			tokens := opr.GetTokens()
+			if i >= len(tokens) || tokens[i].value <= 0 {
+				continue
+			}
+			values = append(values, weightedValue{
+				value:  tokens[i].value,
+				weight: math.Log2(float64(opr.Difficulty) + 1),
+			})
+		}
+
+		consensus[i] = trimmedWeightedMedian(values)
+	}
+
+	return consensus
+}
+
+// trimmedWeightedMedian sorts values ascending, trims trimFraction of the
+// population from each tail, and returns the weighted median of what remains.
+func trimmedWeightedMedian(values []weightedValue) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].value < values[j].value })
+
+	trim := int(float64(len(values)) * trimFraction)
+	trimmed := values[trim : len(values)-trim]
+	if len(trimmed) == 0 {
+		trimmed = values
+	}
+
+	var totalWeight float64
+	for _, v := range trimmed {
+		totalWeight += v.weight
+	}
+	if totalWeight == 0 {
+		return trimmed[len(trimmed)/2].value
+	}
+
+	var cumulative float64
+	for _, v := range trimmed {
+		cumulative += v.weight
+		if cumulative >= totalWeight/2 {
+			return v.value
+		}
+	}
+	return trimmed[len(trimmed)-1].value
+}
+
+// GradeMinimumV3 is GradeMinimum's V3 counterpart: it grades the top 50
+// honest records against RobustConsensus instead of Avg.
+func GradeMinimumV3(sortedList []*OraclePriceRecord) (graded []*OraclePriceRecord) {
+	list := RemoveDuplicateSubmissions(sortedList)
+	if len(list) < 10 {
+		return nil
+	}
+
+	top50 := make([]*OraclePriceRecord, 0)
+	for _, opr := range sortedList {
+		opr.Difficulty = opr.ComputeDifficulty(opr.Nonce)
+		f := binary.BigEndian.Uint64(opr.SelfReportedDifficulty)
+		if f != opr.Difficulty {
+			continue
+		}
+
+		top50 = append(top50, opr)
+		if len(top50) == 50 {
+			break
+		}
+	}
+
+	for i := len(top50); i >= 10; i-- {
+		center := RobustConsensus(top50[:i])
+		for j := 0; j < i; j++ {
+			CalculateGrade(center, top50[j])
+		}
+		slices.SortStableFunc(top50[:i], byDifficultyDesc)
+		slices.SortStableFunc(top50[:i], byGradeAsc)
+	}
+	return top50
+}
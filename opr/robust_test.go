@@ -0,0 +1,33 @@
+// Copyright (c) of parts are held by the various contributors (see the CLA)
+// Licensed under the MIT License. See LICENSE file in the project root for full license information.
+
+package opr
+
+import "testing"
+
+// TestTrimmedWeightedMedianResistsOutlier exercises the core of
+// RobustConsensus: a single extreme misreport, once past the trim, should
+// not move the weighted median by more than a small bounded epsilon.
+// RobustConsensus itself takes []*OraclePriceRecord, whose definition isn't
+// part of this package's tree, so this test drives the aggregation directly
+// against weightedValue instead.
+func TestTrimmedWeightedMedianResistsOutlier(t *testing.T) {
+	const epsilon = 0.01
+
+	baseline := make([]weightedValue, 0, 20)
+	for i := 0; i < 20; i++ {
+		baseline = append(baseline, weightedValue{value: 1.0, weight: 10})
+	}
+	before := trimmedWeightedMedian(baseline)
+
+	withOutlier := append(baseline, weightedValue{value: 1_000_000, weight: 10})
+	after := trimmedWeightedMedian(withOutlier)
+
+	diff := after - before
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > epsilon {
+		t.Fatalf("single outlier moved the median by %v, want <= %v (before=%v after=%v)", diff, epsilon, before, after)
+	}
+}